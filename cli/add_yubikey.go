@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/99designs/aws-vault/vault"
 	"github.com/99designs/keyring"
@@ -9,10 +13,13 @@ import (
 )
 
 type AddYubikeyCommandInput struct {
-	ProfileName  string
-	Keyring      keyring.Keyring
-	Username     string
-	RequireTouch bool
+	ProfileName         string
+	Keyring             keyring.Keyring
+	Username            string
+	RequireTouch        bool
+	MfaBackend          string
+	YubiCloudCredential string
+	LockTimeout         time.Duration
 }
 
 func ConfigureAddYubikeyCommand(app *kingpin.Application) {
@@ -31,6 +38,17 @@ func ConfigureAddYubikeyCommand(app *kingpin.Application) {
 	cmd.Flag("touch", "Require Yubikey touch to generate OTP").
 		BoolVar(&input.RequireTouch)
 
+	cmd.Flag("mfa-backend", "MFA device backend to use").
+		Default(vault.DefaultMfaBackend).
+		StringVar(&input.MfaBackend)
+
+	cmd.Flag("yubicloud-credential", "YubiCloud API client ID and secret key, as <client-id>:<secret-key>; only used with --mfa-backend=yubicloud").
+		StringVar(&input.YubiCloudCredential)
+
+	cmd.Flag("lock-timeout", "Max time to wait for another aws-vault process using this profile").
+		Default(vault.DefaultLockTimeout.String()).
+		DurationVar(&input.LockTimeout)
+
 	cmd.Action(func(c *kingpin.ParseContext) error {
 		input.Keyring = keyringImpl
 		AddYubikeyCommand(app, input)
@@ -48,11 +66,28 @@ func AddYubikeyCommand(app *kingpin.Application, input AddYubikeyCommandInput) {
 		Keyring:        input.Keyring,
 		Username:       input.Username,
 		ProfileSection: p,
+		MfaBackend:     input.MfaBackend,
+		Prompt:         &CLIHardwareKeyPrompt{Writer: os.Stdout},
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+	defer func() {
+		signal.Stop(sigCh)
+		cancel()
+	}()
+
 	fmt.Printf("Adding yubikey to user %s using profile %s\n", input.Username, input.ProfileName)
 
-	if err := yubikey.Register(p.Name, input.RequireTouch); err != nil {
+	err := vault.WithLock(p.Name, input.LockTimeout, func() error {
+		return yubikey.Register(ctx, p.Name, input.RequireTouch, []byte(input.YubiCloudCredential))
+	})
+	if err != nil {
 		app.Fatalf("error registering yubikey", err)
 	}
 
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// CLIHardwareKeyPrompt is the default vault.HardwareKeyPrompt: it prints
+// prompts to Writer (the app's configured output) and reads responses from
+// stdin, cancelling cleanly if ctx is done first.
+type CLIHardwareKeyPrompt struct {
+	Writer io.Writer
+}
+
+// Touch prints a reminder to tap the hardware key and waits for either
+// confirmation on stdin or ctx to be cancelled (e.g. the user pressing Ctrl-C).
+func (p *CLIHardwareKeyPrompt) Touch(ctx context.Context, serial, reason string) error {
+	msg := fmt.Sprintf("Tap your YubiKey for %s", serial)
+	if reason != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, reason)
+	}
+	fmt.Fprintln(p.Writer, msg)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		result <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-result:
+		return err
+	}
+}
+
+// PIN asks for the hardware key's PIN on stdin, cancelling cleanly if ctx is
+// done first.
+func (p *CLIHardwareKeyPrompt) PIN(ctx context.Context, serial string) (string, error) {
+	fmt.Fprintf(p.Writer, "Enter PIN for %s: ", serial)
+
+	type result struct {
+		pin string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		done <- result{strings.TrimSpace(line), err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-done:
+		return r.pin, r.err
+	}
+}
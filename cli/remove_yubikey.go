@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/99designs/keyring"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -15,6 +19,9 @@ type RemoveYubikeyCommandInput struct {
 	Keyring     keyring.Keyring
 	Username    string
 	Config      vault.Config
+	MfaDevice   string
+	MfaBackend  string
+	LockTimeout time.Duration
 }
 
 func ConfigureRemoveYubikeyCommand(app *kingpin.Application) {
@@ -30,6 +37,17 @@ func ConfigureRemoveYubikeyCommand(app *kingpin.Application) {
 		HintAction(awsConfigFile.ProfileNames).
 		StringVar(&input.ProfileName)
 
+	cmd.Flag("mfa-device", "Name of the MFA device to remove, if the user has more than one").
+		StringVar(&input.MfaDevice)
+
+	cmd.Flag("mfa-backend", "MFA device backend to use").
+		Default(vault.DefaultMfaBackend).
+		StringVar(&input.MfaBackend)
+
+	cmd.Flag("lock-timeout", "Max time to wait for another aws-vault process using this profile").
+		Default(vault.DefaultLockTimeout.String()).
+		DurationVar(&input.LockTimeout)
+
 	cmd.Action(func(c *kingpin.ParseContext) error {
 		input.Keyring = keyringImpl
 		RemoveYubikeyCommand(app, input)
@@ -53,11 +71,35 @@ func RemoveYubikeyCommand(app *kingpin.Application, input RemoveYubikeyCommandIn
 		Keyring:        input.Keyring,
 		Username:       input.Username,
 		ProfileSection: p,
+		MfaBackend:     input.MfaBackend,
+		Prompt:         &CLIHardwareKeyPrompt{Writer: os.Stdout},
+	}
+
+	// --mfa-device takes priority over the profile's mfa-device config, which in
+	// turn takes priority over the single-device fallback in vault.Yubikey.Remove.
+	mfaDevice := input.MfaDevice
+	if mfaDevice == "" {
+		mfaDevice = p.MfaDevice
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+	defer func() {
+		signal.Stop(sigCh)
+		cancel()
+	}()
+
 	fmt.Printf("Removing yubikey for user %s using profile %s\n", input.Username, input.ProfileName)
 
-	if err := yubikey.Remove(input.ProfileName, val); err != nil {
+	err = vault.WithLock(p.Name, input.LockTimeout, func() error {
+		return yubikey.Remove(ctx, input.ProfileName, val, mfaDevice)
+	})
+	if err != nil {
 		app.Fatalf("error removing yubikey", err)
 		return
 	}
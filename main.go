@@ -6,6 +6,10 @@ import (
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/99designs/aws-vault/cli"
+
+	// Register the built-in MFA device backends.
+	_ "github.com/99designs/aws-vault/mfa/device/yubicloud"
+	_ "github.com/99designs/aws-vault/mfa/device/yubikey"
 )
 
 // Version is provided at compile time
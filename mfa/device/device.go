@@ -0,0 +1,81 @@
+// Package device defines the interface MFA device backends implement, and a
+// registry so backends can be selected at runtime by name (e.g. via a
+// --mfa-backend flag) instead of being wired in directly.
+package device
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// ReaderManager is implemented by MFA device backends that can enroll, read
+// and remove one-time-password credentials for named devices.
+type ReaderManager interface {
+	// Name identifies the backend, e.g. for log messages and error wrapping.
+	Name() string
+
+	// Add enrolls a new named device with the given seed.
+	Add(name string, secret []byte) error
+
+	// Delete removes a previously enrolled named device.
+	Delete(name string) error
+
+	// GetOTP returns the one-time-password for name valid at time t. ctx is
+	// honored by backends that need to prompt for a touch or PIN to produce it.
+	GetOTP(ctx context.Context, t time.Time, name string) (string, error)
+}
+
+// TouchRequirer is implemented by backends that can be told to require a
+// touch confirmation for the credential being added (the yubikey backend
+// asks for it before each code it computes). Not every backend supports this, so
+// callers should check for it rather than assume it.
+type TouchRequirer interface {
+	RequireAddTouch(require bool)
+}
+
+// ExternallyProvisioned is implemented by backends whose Add credential isn't
+// the AWS-issued virtual MFA seed from iam.CreateVirtualMFADevice, but
+// something provisioned out of band instead (e.g. yubicloud's YubiCloud API
+// client ID and secret key). Callers that enroll a new device should check
+// for this and supply the right payload to Add rather than assuming the seed.
+type ExternallyProvisioned interface {
+	ExternallyProvisioned() bool
+}
+
+// Factory constructs a ReaderManager for a registered backend. kr is the same
+// keyring used for AWS credentials, for backends that need somewhere to
+// store their own secrets (e.g. YubiCloud API credentials).
+type Factory func(kr keyring.Keyring) (ReaderManager, error)
+
+var backends = map[string]Factory{}
+
+// RegisterDevice makes a backend available under name, for later lookup with
+// Get. It's intended to be called from the init() of a backend package.
+func RegisterDevice(name string, factory Factory) {
+	if _, exists := backends[name]; exists {
+		panic(fmt.Sprintf("device: backend %q already registered", name))
+	}
+	backends[name] = factory
+}
+
+// Get constructs the backend registered under name.
+func Get(name string, kr keyring.Keyring) (ReaderManager, error) {
+	factory, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("device: no such backend %q", name)
+	}
+	return factory(kr)
+}
+
+// Names returns the names of all registered backends, for use in flag help
+// text and validation.
+func Names() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	return names
+}
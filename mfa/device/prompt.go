@@ -0,0 +1,22 @@
+package device
+
+import "context"
+
+// HardwareKeyPrompt lets a host (CLI, GUI wrapper, editor plugin) control how
+// a user is asked to interact with a hardware key, instead of each backend
+// hard-coding its own console output.
+type HardwareKeyPrompt interface {
+	// Touch asks the user to touch their hardware key to confirm an action.
+	// reason describes what's being confirmed, e.g. "enrolling new credential".
+	// Implementations should honor ctx cancellation while waiting.
+	Touch(ctx context.Context, serial, reason string) error
+
+	// PIN asks the user for their hardware key's PIN, honoring ctx cancellation.
+	PIN(ctx context.Context, serial string) (string, error)
+}
+
+// PromptAware is implemented by backends that can surface touch/PIN prompts
+// through a HardwareKeyPrompt, rather than writing to the console directly.
+type PromptAware interface {
+	SetPrompt(prompt HardwareKeyPrompt)
+}
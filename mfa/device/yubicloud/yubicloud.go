@@ -0,0 +1,279 @@
+// Package yubicloud implements an MFA device backend for YubiKeys that only
+// expose OTP (not OATH) slots, such as many corporate-provisioned keys. Seeds
+// aren't stored locally: instead, the OTP typed by the YubiKey on touch is
+// verified against Yubico's YubiCloud validation service.
+package yubicloud
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/99designs/aws-vault/mfa/device"
+	"github.com/99designs/keyring"
+	"github.com/pkg/errors"
+)
+
+const (
+	backendName = "yubicloud"
+	keyringItem = "yubicloud-api-credentials"
+	httpTimeout = 10 * time.Second
+)
+
+// validationURL is a var so tests can point it at a local server.
+var validationURL = "https://api.yubico.com/wsapi/2.0/verify"
+
+var httpClient = &http.Client{Timeout: httpTimeout}
+
+func init() {
+	device.RegisterDevice(backendName, func(kr keyring.Keyring) (device.ReaderManager, error) {
+		return New(kr)
+	})
+}
+
+// credentials are the YubiCloud API client ID and secret key issued by
+// https://upgrade.yubico.com/getapikey/, stored in the same keyring as AWS
+// credentials.
+type credentials struct {
+	ClientID  string `json:"client_id"`
+	SecretKey string `json:"secret_key"`
+}
+
+// Device verifies OTPs emitted by a YubiKey against YubiCloud, rather than
+// generating them from a locally held seed.
+type Device struct {
+	keyring keyring.Keyring
+	prompt  device.HardwareKeyPrompt
+}
+
+// New returns a Device that validates OTPs against YubiCloud using client
+// credentials read from kr.
+func New(kr keyring.Keyring) (*Device, error) {
+	return &Device{keyring: kr}, nil
+}
+
+// Name identifies this backend.
+func (d *Device) Name() string {
+	return backendName
+}
+
+// ExternallyProvisioned reports that this backend's credentials are a
+// YubiCloud API client ID and secret key, not an AWS-issued virtual MFA
+// seed, so callers enrolling a new device should pass that instead.
+func (d *Device) ExternallyProvisioned() bool {
+	return true
+}
+
+// SetPrompt configures how the user is asked to touch the key to produce an
+// OTP. Without one set, GetOTP falls back to a plain stderr message.
+func (d *Device) SetPrompt(prompt device.HardwareKeyPrompt) {
+	d.prompt = prompt
+}
+
+// Add stores the YubiCloud client ID and secret key for name. secret is
+// "<client-id>:<secret-key>", matching the credentials issued by Yubico.
+func (d *Device) Add(name string, secret []byte) error {
+	parts := strings.SplitN(string(secret), ":", 2)
+	if len(parts) != 2 {
+		return errors.New("yubicloud credential must be formatted as <client-id>:<secret-key>")
+	}
+
+	return d.store(name, credentials{ClientID: parts[0], SecretKey: parts[1]})
+}
+
+// Delete removes the stored YubiCloud client credentials for name.
+func (d *Device) Delete(name string) error {
+	return d.keyring.Remove(d.itemKey(name))
+}
+
+// GetOTP prompts the user to touch their YubiKey, reads the OTP it types,
+// verifies it against YubiCloud and returns it if valid.
+func (d *Device) GetOTP(ctx context.Context, t time.Time, name string) (string, error) {
+	creds, err := d.load(name)
+	if err != nil {
+		return "", err
+	}
+
+	if d.prompt != nil {
+		if err := d.prompt.Touch(ctx, name, "generate OTP"); err != nil {
+			return "", err
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, "Touch your YubiKey now...")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	otp, err := reader.ReadString('\n')
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read OTP from YubiKey")
+	}
+	otp = strings.TrimSpace(otp)
+
+	if err := verify(creds, otp); err != nil {
+		return "", errors.Wrap(err, "YubiCloud rejected OTP")
+	}
+
+	return otp, nil
+}
+
+func (d *Device) itemKey(name string) string {
+	return fmt.Sprintf("%s:%s", keyringItem, name)
+}
+
+func (d *Device) store(name string, creds credentials) error {
+	return d.keyring.Set(keyring.Item{
+		Key:  d.itemKey(name),
+		Data: []byte(fmt.Sprintf("%s:%s", creds.ClientID, creds.SecretKey)),
+	})
+}
+
+func (d *Device) load(name string) (credentials, error) {
+	item, err := d.keyring.Get(d.itemKey(name))
+	if err != nil {
+		return credentials{}, errors.Wrapf(err, "no YubiCloud credentials found for %q", name)
+	}
+
+	parts := strings.SplitN(string(item.Data), ":", 2)
+	if len(parts) != 2 {
+		return credentials{}, errors.Errorf("corrupt YubiCloud credentials for %q", name)
+	}
+
+	return credentials{ClientID: parts[0], SecretKey: parts[1]}, nil
+}
+
+// verify checks otp against the YubiCloud validation service, signing the
+// request with creds.SecretKey as the YubiCloud API requires. The response's
+// own signature is verified against creds.SecretKey and its nonce/otp are
+// checked against what was sent, so a MITM or replayed response can't forge
+// a success.
+func verify(creds credentials, otp string) error {
+	nonce, err := randomNonce()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate nonce")
+	}
+
+	params := url.Values{
+		"id":    {creds.ClientID},
+		"otp":   {otp},
+		"nonce": {nonce},
+	}
+
+	sig, err := sign(params, creds.SecretKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign YubiCloud request")
+	}
+	params.Set("h", sig)
+
+	resp, err := httpClient.Get(validationURL + "?" + params.Encode())
+	if err != nil {
+		return errors.Wrap(err, "failed to contact YubiCloud")
+	}
+	defer resp.Body.Close()
+
+	reply, err := parseResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	want, err := sign(withoutSig(reply), creds.SecretKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign YubiCloud response for comparison")
+	}
+	if got := reply.Get("h"); got != want {
+		return errors.New("YubiCloud response signature did not verify")
+	}
+
+	if reply.Get("nonce") != nonce {
+		return errors.New("YubiCloud response nonce did not match the request")
+	}
+
+	if reply.Get("otp") != otp {
+		return errors.New("YubiCloud response otp did not match the request")
+	}
+
+	if status := reply.Get("status"); status != "OK" {
+		return errors.Errorf("YubiCloud returned status %q", status)
+	}
+
+	return nil
+}
+
+// parseResponse reads a YubiCloud "key=value" response body into url.Values,
+// the same shape the request parameters are built from so it can be re-signed.
+func parseResponse(resp *http.Response) (url.Values, error) {
+	values := url.Values{}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		values.Set(parts[0], parts[1])
+	}
+
+	if values.Get("status") == "" {
+		return nil, errors.New("no status in YubiCloud response")
+	}
+
+	return values, nil
+}
+
+// withoutSig returns params with the "h" field removed, since it's excluded
+// from the data that's signed.
+func withoutSig(params url.Values) url.Values {
+	out := url.Values{}
+	for k, v := range params {
+		if k == "h" {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// sign computes the base64 HMAC-SHA1 signature YubiCloud expects over the
+// request parameters, sorted by key as required by the protocol. The API
+// secret key is issued base64-encoded and must be decoded before use as the
+// HMAC key.
+func sign(params url.Values, secretKey string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(secretKey)
+	if err != nil {
+		return "", errors.Wrap(err, "YubiCloud secret key is not valid base64")
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, params.Get(k)))
+	}
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write([]byte(strings.Join(pairs, "&")))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", b), nil
+}
@@ -0,0 +1,118 @@
+package yubicloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// Golden vector: secretKey is base64("thisisasecretkey"), computed
+	// independently with Python's hmac/hashlib to pin the exact bytes HMAC'd.
+	const secretKey = "dGhpc2lzYXNlY3JldGtleQ=="
+	const want = "IqQdeGnSB96tR7Kkntjmocpgl8s="
+
+	params := url.Values{
+		"id":    {"1234"},
+		"nonce": {"abcdef0123456789"},
+		"otp":   {"ccccccfhcbbcjbdeirdfjgjrcjhgdttrbiedjibbfcif"},
+	}
+
+	got, err := sign(params, secretKey)
+	if err != nil {
+		t.Fatalf("sign returned error: %v", err)
+	}
+	if got != want {
+		t.Errorf("sign() = %q, want %q", got, want)
+	}
+}
+
+func TestSignInvalidSecretKey(t *testing.T) {
+	_, err := sign(url.Values{"id": {"1234"}}, "not-valid-base64!!")
+	if err == nil {
+		t.Fatal("expected an error for a non-base64 secret key, got nil")
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	body := "h=abc\r\nt=2020-01-01T00:00:00Z0000\r\notp=cccc\r\nnonce=deadbeef\r\nsl=100\r\nstatus=OK\r\n"
+	resp := &http.Response{Body: ioutil.NopCloser(strings.NewReader(body))}
+
+	values, err := parseResponse(resp)
+	if err != nil {
+		t.Fatalf("parseResponse returned error: %v", err)
+	}
+
+	if values.Get("status") != "OK" {
+		t.Errorf("status = %q, want OK", values.Get("status"))
+	}
+	if values.Get("otp") != "cccc" {
+		t.Errorf("otp = %q, want cccc", values.Get("otp"))
+	}
+}
+
+func TestParseResponseMissingStatus(t *testing.T) {
+	resp := &http.Response{Body: ioutil.NopCloser(strings.NewReader("h=abc\r\notp=cccc\r\n"))}
+
+	if _, err := parseResponse(resp); err == nil {
+		t.Fatal("expected an error when the response has no status field, got nil")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const clientID = "1234"
+	const secretKey = "dGhpc2lzYXNlY3JldGtleQ=="
+	const otp = "ccccccfhcbbcjbdeirdfjgjrcjhgdttrbiedjibbfcif"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		reply := url.Values{
+			"otp":    {q.Get("otp")},
+			"nonce":  {q.Get("nonce")},
+			"status": {"OK"},
+		}
+		sig, err := sign(reply, secretKey)
+		if err != nil {
+			panic(err) // secretKey is a valid constant above, this can't fail
+		}
+		reply.Set("h", sig)
+
+		for _, k := range []string{"h", "otp", "nonce", "status"} {
+			fmt.Fprintf(w, "%s=%s\r\n", k, reply.Get(k))
+		}
+	}))
+	defer server.Close()
+
+	old := validationURL
+	validationURL = server.URL
+	defer func() { validationURL = old }()
+
+	if err := verify(credentials{ClientID: clientID, SecretKey: secretKey}, otp); err != nil {
+		t.Fatalf("verify returned error for a valid signed response: %v", err)
+	}
+}
+
+func TestVerifyRejectsBadSignature(t *testing.T) {
+	const secretKey = "dGhpc2lzYXNlY3JldGtleQ=="
+	const otp = "ccccccfhcbbcjbdeirdfjgjrcjhgdttrbiedjibbfcif"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		fmt.Fprintf(w, "h=not-a-real-signature\r\notp=%s\r\nnonce=%s\r\nstatus=OK\r\n", q.Get("otp"), q.Get("nonce"))
+	}))
+	defer server.Close()
+
+	old := validationURL
+	validationURL = server.URL
+	defer func() { validationURL = old }()
+
+	err := verify(credentials{ClientID: "1234", SecretKey: secretKey}, otp)
+	if err == nil {
+		t.Fatal("expected verify to reject a response with a bad signature, got nil error")
+	}
+}
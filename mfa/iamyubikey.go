@@ -1,8 +1,13 @@
 package mfa
 
 import (
+	"bufio"
+	"context"
 	"encoding/base32"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,15 +36,23 @@ func New(sess *session.Session, d device.ReaderManager) (*MFA, error) {
 	}, nil
 }
 
-// Add adds a Yubikey as a virtual MFA
-func (m *MFA) Add(username string) (*string, []byte, error) {
+// Add adds a Yubikey as a virtual MFA. extCred is only used for backends that
+// implement device.ExternallyProvisioned (e.g. yubicloud's client ID and
+// secret key); for the default yubikey backend it's ignored and the
+// AWS-generated seed is stored instead.
+func (m *MFA) Add(ctx context.Context, username string, extCred []byte) (*string, []byte, error) {
 	serial, secret, err := m.create(username)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if err := m.enable(username, serial, secret); err != nil {
+	stored := secret
+	if ep, ok := m.device.(device.ExternallyProvisioned); ok && ep.ExternallyProvisioned() {
+		stored = extCred
+	}
+
+	if err := m.enable(ctx, username, serial, stored); err != nil {
 		return nil, nil, err
 	}
 
@@ -47,21 +60,24 @@ func (m *MFA) Add(username string) (*string, []byte, error) {
 }
 
 // Delete removes a virtual MFA from the source including it's association with
-// the given IAM username
-func (m *MFA) Delete(username string) error {
-	res, err := m.sts.GetCallerIdentity(&sts.GetCallerIdentityInput{})
-
-	if err != nil {
-		return errors.Wrap(err, "failed to determine serial number for device deletion")
-	}
+// the given IAM username. If serial is empty the current caller's own MFA
+// serial is used, preserving the historical single-device behavior.
+func (m *MFA) Delete(username string, serial string) error {
+	if serial == "" {
+		res, err := m.sts.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+
+		if err != nil {
+			return errors.Wrap(err, "failed to determine serial number for device deletion")
+		}
 
-	serial, err := callerIdentityToSerial(res.Arn)
+		serial, err = callerIdentityToSerial(res.Arn)
 
-	if err != nil {
-		return err
+		if err != nil {
+			return err
+		}
 	}
 
-	err = m.deactivate(username, &serial)
+	err := m.deactivate(username, &serial)
 	if err != nil {
 		return err
 	}
@@ -73,6 +89,95 @@ func (m *MFA) Delete(username string) error {
 	return nil
 }
 
+// ListDevices returns the MFA devices currently associated with username, so
+// callers can resolve a device name to a serial or prompt the user to choose
+// between multiple devices.
+func (m *MFA) ListDevices(username string) ([]*iam.MFADevice, error) {
+	res, err := m.iam.ListMFADevices(&iam.ListMFADevicesInput{
+		UserName: &username,
+	})
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list MFA devices for %q", username)
+	}
+
+	return res.MFADevices, nil
+}
+
+// ResolveSerial turns a --mfa-device name (a suffix of the device serial, e.g.
+// a Yubikey's own name) into a full MFA serial number. When deviceName is
+// empty it falls back to the caller's own MFA serial, matching the behavior
+// before multiple MFA devices per user were supported. If deviceName doesn't
+// match any device, the user's devices are listed so they can pick one
+// interactively.
+//
+// remove-yubikey wires a --mfa-device flag through to this; exec and login
+// still need the same flag and a call to ResolveSerial to let users pick a
+// device for the session-token path, but those commands live outside this
+// tree.
+func (m *MFA) ResolveSerial(username, deviceName string) (string, error) {
+	if deviceName == "" {
+		res, err := m.sts.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to determine serial number for device")
+		}
+
+		return callerIdentityToSerial(res.Arn)
+	}
+
+	devices, err := m.ListDevices(username)
+	if err != nil {
+		return "", err
+	}
+
+	if serial, ok := matchDeviceByName(devices, deviceName); ok {
+		return serial, nil
+	}
+
+	fmt.Printf("No MFA device named %q found for user %q, please choose one:\n", deviceName, username)
+
+	return promptForDevice(os.Stdin, devices)
+}
+
+// matchDeviceByName returns the serial of the device in devices whose serial
+// ends in "/"+name, e.g. matching a Yubikey's own name against the ARN-style
+// serial IAM assigns it.
+func matchDeviceByName(devices []*iam.MFADevice, name string) (string, bool) {
+	for _, d := range devices {
+		if d.SerialNumber != nil && strings.HasSuffix(*d.SerialNumber, "/"+name) {
+			return *d.SerialNumber, true
+		}
+	}
+	return "", false
+}
+
+// promptForDevice lists the given MFA devices and asks the user to pick one
+// by number from r, returning its serial number.
+func promptForDevice(r io.Reader, devices []*iam.MFADevice) (string, error) {
+	if len(devices) == 0 {
+		return "", errors.New("no MFA devices found for user")
+	}
+
+	for i, d := range devices {
+		fmt.Printf("  %d) %s\n", i+1, *d.SerialNumber)
+	}
+
+	reader := bufio.NewReader(r)
+	fmt.Print("Enter the number of the MFA device: ")
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read device selection")
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(devices) {
+		return "", errors.Errorf("invalid selection %q", strings.TrimSpace(line))
+	}
+
+	return *devices[choice-1].SerialNumber, nil
+}
+
 // create creates the virtual MFA device
 func (m *MFA) create(username string) (*string, []byte, error) {
 	res, err := m.iam.CreateVirtualMFADevice(&iam.CreateVirtualMFADeviceInput{
@@ -92,7 +197,7 @@ func (m *MFA) create(username string) (*string, []byte, error) {
 	return res.VirtualMFADevice.SerialNumber, secret, nil
 }
 
-func (m *MFA) enable(username string, serial *string, secret []byte) error {
+func (m *MFA) enable(ctx context.Context, username string, serial *string, secret []byte) error {
 	name, err := SerialToName(serial)
 	if err != nil {
 		return err
@@ -102,13 +207,13 @@ func (m *MFA) enable(username string, serial *string, secret []byte) error {
 		return errors.Wrapf(err, "error adding source %s %s", name, m.device.Name())
 	}
 
-	otp1, err := m.device.GetOTP(time.Now(), name)
+	otp1, err := m.device.GetOTP(ctx, time.Now(), name)
 
 	if err != nil {
 		return errors.Wrap(err, "error getting first otp")
 	}
 
-	otp2, err := m.device.GetOTP(time.Now().Add(30*time.Second), name)
+	otp2, err := m.device.GetOTP(ctx, time.Now().Add(30*time.Second), name)
 
 	if err != nil {
 		return errors.Wrap(err, "error getting second otp")
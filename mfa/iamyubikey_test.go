@@ -0,0 +1,72 @@
+package mfa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+func serial(s string) *iam.MFADevice {
+	return &iam.MFADevice{SerialNumber: aws.String(s)}
+}
+
+func TestMatchDeviceByName(t *testing.T) {
+	devices := []*iam.MFADevice{
+		serial("arn:aws:iam::123456789012:mfa/aws:arn:aws:iam::123456789012:user/alice"),
+		serial("arn:aws:iam::123456789012:mfa/work-yubikey"),
+	}
+
+	tests := []struct {
+		name       string
+		deviceName string
+		wantSerial string
+		wantOK     bool
+	}{
+		{"exact suffix match", "work-yubikey", "arn:aws:iam::123456789012:mfa/work-yubikey", true},
+		{"no match", "personal-yubikey", "", false},
+		{"empty name never matches", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matchDeviceByName(devices, tt.deviceName)
+			if ok != tt.wantOK || got != tt.wantSerial {
+				t.Errorf("matchDeviceByName(%q) = (%q, %v), want (%q, %v)",
+					tt.deviceName, got, ok, tt.wantSerial, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPromptForDevice(t *testing.T) {
+	devices := []*iam.MFADevice{
+		serial("arn:aws:iam::123456789012:mfa/one"),
+		serial("arn:aws:iam::123456789012:mfa/two"),
+	}
+
+	got, err := promptForDevice(strings.NewReader("2\n"), devices)
+	if err != nil {
+		t.Fatalf("promptForDevice returned error: %v", err)
+	}
+	if want := "arn:aws:iam::123456789012:mfa/two"; got != want {
+		t.Errorf("promptForDevice() = %q, want %q", got, want)
+	}
+}
+
+func TestPromptForDeviceNoDevices(t *testing.T) {
+	if _, err := promptForDevice(strings.NewReader("1\n"), nil); err == nil {
+		t.Fatal("expected an error when there are no devices to choose from, got nil")
+	}
+}
+
+func TestPromptForDeviceInvalidSelection(t *testing.T) {
+	devices := []*iam.MFADevice{serial("arn:aws:iam::123456789012:mfa/one")}
+
+	for _, input := range []string{"0\n", "2\n", "not-a-number\n"} {
+		if _, err := promptForDevice(strings.NewReader(input), devices); err == nil {
+			t.Errorf("promptForDevice(%q) expected an error, got nil", input)
+		}
+	}
+}
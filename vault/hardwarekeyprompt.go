@@ -0,0 +1,8 @@
+package vault
+
+import "github.com/99designs/aws-vault/mfa/device"
+
+// HardwareKeyPrompt lets a host (CLI, GUI wrapper, editor plugin) control how
+// a user is asked to interact with a hardware key, instead of each MFA device
+// backend hard-coding its own console output.
+type HardwareKeyPrompt = device.HardwareKeyPrompt
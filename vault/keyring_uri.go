@@ -0,0 +1,122 @@
+package vault
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/99designs/keyring"
+	"github.com/pkg/errors"
+)
+
+// KeyringURIEnvVar is the environment variable that can supply a keyring URI
+// when none is given via --keyring or a profile's keyring_uri.
+const KeyringURIEnvVar = "AWS_VAULT_KEYRING_URI"
+
+// ResolveKeyringURI picks which keyring URI to use, preferring the most
+// specific source: an explicit flag, then the profile's own keyring_uri
+// (so different profiles can live in different backends), then the env var.
+//
+// Neither this nor ParseKeyringURI is called anywhere yet: wiring a
+// --keyring flag and cli.ConfigureGlobals to call them, and adding the
+// keyring_uri field to ProfileSection that would supply profileURI, all need
+// files (cli/globals.go, the ProfileSection type) that aren't part of this
+// source tree. Until that wiring lands these are unreachable from the CLI.
+func ResolveKeyringURI(flagURI, profileURI string) string {
+	if flagURI != "" {
+		return flagURI
+	}
+	if profileURI != "" {
+		return profileURI
+	}
+	return os.Getenv(KeyringURIEnvVar)
+}
+
+// ParseKeyringURI turns a URI like "keychain://?service=aws-vault",
+// "pass://?dir=~/.password-store&prefix=aws-vault" or
+// "file://~/.aws-vault?passphrase-cmd=..." into a keyring.Config, so users
+// have one composable way to select and configure a backend instead of a
+// handful of separate flags. Query parameters map onto the existing
+// keyring.Config fields for that backend; unknown schemes are an error.
+func ParseKeyringURI(raw string) (keyring.Config, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return keyring.Config{}, errors.Wrapf(err, "invalid keyring URI %q", raw)
+	}
+
+	q := u.Query()
+
+	switch u.Scheme {
+	case "keychain":
+		return keyring.Config{
+			AllowedBackends: []keyring.BackendType{keyring.KeychainBackend},
+			ServiceName:     q.Get("service"),
+			KeychainName:    q.Get("keychain"),
+		}, nil
+
+	case "pass":
+		return keyring.Config{
+			AllowedBackends: []keyring.BackendType{keyring.PassBackend},
+			PassDir:         expandPath(q.Get("dir")),
+			PassPrefix:      q.Get("prefix"),
+			PassCmd:         q.Get("cmd"),
+		}, nil
+
+	case "file":
+		cfg := keyring.Config{
+			AllowedBackends: []keyring.BackendType{keyring.FileBackend},
+			FileDir:         expandPath(u.Host + u.Path),
+		}
+
+		if cmd := q.Get("passphrase-cmd"); cmd != "" {
+			cfg.FilePasswordFunc = passphraseCmdPrompt(cmd)
+		}
+
+		return cfg, nil
+
+	case "wincred":
+		return keyring.Config{
+			AllowedBackends: []keyring.BackendType{keyring.WinCredBackend},
+			WinCredPrefix:   q.Get("prefix"),
+		}, nil
+
+	case "secret-service":
+		return keyring.Config{
+			AllowedBackends:         []keyring.BackendType{keyring.SecretServiceBackend},
+			ServiceName:             q.Get("service"),
+			LibSecretCollectionName: q.Get("collection"),
+		}, nil
+
+	default:
+		return keyring.Config{}, errors.Errorf("unknown keyring backend scheme %q", u.Scheme)
+	}
+}
+
+// expandPath expands a leading "~" to the user's home directory and any
+// $VAR references, so URIs like "file://~/.aws-vault" resolve to a real path
+// instead of creating a literal "~" directory.
+func expandPath(p string) string {
+	p = os.ExpandEnv(p)
+
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+		}
+	}
+
+	return p
+}
+
+// passphraseCmdPrompt runs cmd through the shell to obtain a file backend
+// passphrase, instead of prompting interactively.
+func passphraseCmdPrompt(cmd string) keyring.PromptFunc {
+	return func(_ string) (string, error) {
+		out, err := exec.Command("sh", "-c", cmd).Output()
+		if err != nil {
+			return "", errors.Wrapf(err, "passphrase-cmd %q failed", cmd)
+		}
+		return strings.TrimRight(string(out), "\n"), nil
+	}
+}
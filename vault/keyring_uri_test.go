@@ -0,0 +1,166 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+func TestResolveKeyringURI(t *testing.T) {
+	os.Setenv(KeyringURIEnvVar, "env://from-env")
+	defer os.Unsetenv(KeyringURIEnvVar)
+
+	tests := []struct {
+		name       string
+		flagURI    string
+		profileURI string
+		want       string
+	}{
+		{"flag wins over profile and env", "flag://x", "profile://y", "flag://x"},
+		{"profile wins over env", "", "profile://y", "profile://y"},
+		{"falls back to env", "", "", "env://from-env"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveKeyringURI(tt.flagURI, tt.profileURI); got != tt.want {
+				t.Errorf("ResolveKeyringURI(%q, %q) = %q, want %q", tt.flagURI, tt.profileURI, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKeyringURI(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want keyring.Config
+	}{
+		{
+			name: "keychain",
+			uri:  "keychain://?service=aws-vault&keychain=login",
+			want: keyring.Config{
+				AllowedBackends: []keyring.BackendType{keyring.KeychainBackend},
+				ServiceName:     "aws-vault",
+				KeychainName:    "login",
+			},
+		},
+		{
+			name: "pass",
+			uri:  "pass://?dir=%2Fhome%2Falice%2F.password-store&prefix=aws-vault&cmd=pass",
+			want: keyring.Config{
+				AllowedBackends: []keyring.BackendType{keyring.PassBackend},
+				PassDir:         "/home/alice/.password-store",
+				PassPrefix:      "aws-vault",
+				PassCmd:         "pass",
+			},
+		},
+		{
+			name: "wincred",
+			uri:  "wincred://?prefix=aws-vault",
+			want: keyring.Config{
+				AllowedBackends: []keyring.BackendType{keyring.WinCredBackend},
+				WinCredPrefix:   "aws-vault",
+			},
+		},
+		{
+			name: "secret-service",
+			uri:  "secret-service://?service=aws-vault&collection=login",
+			want: keyring.Config{
+				AllowedBackends:         []keyring.BackendType{keyring.SecretServiceBackend},
+				ServiceName:             "aws-vault",
+				LibSecretCollectionName: "login",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKeyringURI(tt.uri)
+			if err != nil {
+				t.Fatalf("ParseKeyringURI(%q) returned error: %v", tt.uri, err)
+			}
+
+			if !configsEqual(got, tt.want) {
+				t.Errorf("ParseKeyringURI(%q) = %+v, want %+v", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKeyringURIFileExpandsHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory available: %v", err)
+	}
+
+	cfg, err := ParseKeyringURI("file://~/.aws-vault")
+	if err != nil {
+		t.Fatalf("ParseKeyringURI returned error: %v", err)
+	}
+
+	want := filepath.Join(home, ".aws-vault")
+	if cfg.FileDir != want {
+		t.Errorf("FileDir = %q, want %q", cfg.FileDir, want)
+	}
+}
+
+func TestParseKeyringURIUnknownScheme(t *testing.T) {
+	if _, err := ParseKeyringURI("bogus://whatever"); err == nil {
+		t.Fatal("expected an error for an unknown scheme, got nil")
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory available: %v", err)
+	}
+
+	os.Setenv("AWS_VAULT_TEST_DIR", "myvault")
+	defer os.Unsetenv("AWS_VAULT_TEST_DIR")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"tilde only", "~", home},
+		{"tilde with path", "~/.aws-vault", filepath.Join(home, ".aws-vault")},
+		{"env var", "$AWS_VAULT_TEST_DIR/store", "myvault/store"},
+		{"absolute path untouched", "/etc/aws-vault", "/etc/aws-vault"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandPath(tt.in); got != tt.want {
+				t.Errorf("expandPath(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func configsEqual(a, b keyring.Config) bool {
+	a.FilePasswordFunc = nil
+	b.FilePasswordFunc = nil
+
+	if len(a.AllowedBackends) != len(b.AllowedBackends) {
+		return false
+	}
+	for i := range a.AllowedBackends {
+		if a.AllowedBackends[i] != b.AllowedBackends[i] {
+			return false
+		}
+	}
+
+	return a.ServiceName == b.ServiceName &&
+		a.KeychainName == b.KeychainName &&
+		a.PassDir == b.PassDir &&
+		a.PassPrefix == b.PassPrefix &&
+		a.PassCmd == b.PassCmd &&
+		a.FileDir == b.FileDir &&
+		a.WinCredPrefix == b.WinCredPrefix &&
+		a.LibSecretCollectionName == b.LibSecretCollectionName
+}
@@ -1,6 +1,7 @@
 package vault
 
 import (
+	"context"
 	"encoding/base32"
 	"fmt"
 	"log"
@@ -14,18 +15,41 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/99designs/aws-vault/mfa"
-	"github.com/99designs/aws-vault/mfa/device/yubikey"
+	"github.com/99designs/aws-vault/mfa/device"
 )
 
+// DefaultMfaBackend is the device backend used when none is specified. That
+// backend keeps the AWS virtual MFA TOTP seed in the keyring and computes
+// codes in software; it does not program a YubiKey's OATH applet and does not
+// support HOTP.
+const DefaultMfaBackend = "yubikey"
+
 // Yubikey represents a yubikey config
+//
+// MfaBackend is wired to a --mfa-backend flag on add-yubikey and
+// remove-yubikey. exec also needs the flag, since it's the command that
+// actually calls GetSessionToken against the backend, but cli/exec.go isn't
+// part of this source tree.
 type Yubikey struct {
 	Keyring        keyring.Keyring
 	Username       string
 	ProfileSection ProfileSection
+	MfaBackend     string
+	Prompt         HardwareKeyPrompt
+}
+
+func (y *Yubikey) backendName() string {
+	if y.MfaBackend == "" {
+		return DefaultMfaBackend
+	}
+	return y.MfaBackend
 }
 
-// Create adds a yubikey as a device device for an iam user and stores the config in a keychain
-func (y *Yubikey) Register(profile string, requireTouch bool) error {
+// Register adds a yubikey as a device device for an iam user and stores the
+// config in a keychain. extCred is only used for backends that store
+// credentials provisioned separately from the AWS virtual MFA seed (see
+// device.ExternallyProvisioned), e.g. yubicloud's "<client-id>:<secret-key>".
+func (y *Yubikey) Register(ctx context.Context, profile string, requireTouch bool, extCred []byte) error {
 	var err error
 
 	provider := &KeyringProvider{
@@ -54,30 +78,40 @@ func (y *Yubikey) Register(profile string, requireTouch bool) error {
 		masterCreds.AccessKeyID[len(masterCreds.AccessKeyID)-4:],
 		currentUserName)
 
-	device, err := yubikey.New()
+	dev, err := device.Get(y.backendName(), y.Keyring)
 	if err != nil {
 		return err
 	}
-	device.RequireAddTouch(requireTouch)
+	if tr, ok := dev.(device.TouchRequirer); ok {
+		tr.RequireAddTouch(requireTouch)
+	}
+	if pa, ok := dev.(device.PromptAware); ok && y.Prompt != nil {
+		pa.SetPrompt(y.Prompt)
+	}
 
-	m, err := mfa.New(sess, device)
+	m, err := mfa.New(sess, dev)
 	if err != nil {
 		return err
 	}
 
-	serial, secret, err := m.Add(y.Username)
+	serial, secret, err := m.Add(ctx, y.Username, extCred)
 	if err != nil {
 		return err
 	}
 
-	uri := fmt.Sprintf("otpauth://totp/%s@%s?secret=%s&issuer=%s",
-		y.Username,
-		y.ProfileSection.Name,
-		base32.StdEncoding.EncodeToString(secret),
-		"Amazon",
-	)
-
-	qrterminal.Generate(uri, qrterminal.L, os.Stderr)
+	// Only the default yubikey backend generates codes from this seed
+	// locally; externally provisioned backends like yubicloud have nothing
+	// for a TOTP authenticator app to scan.
+	if ep, ok := dev.(device.ExternallyProvisioned); !ok || !ep.ExternallyProvisioned() {
+		uri := fmt.Sprintf("otpauth://totp/%s@%s?secret=%s&issuer=%s",
+			y.Username,
+			y.ProfileSection.Name,
+			base32.StdEncoding.EncodeToString(secret),
+			"Amazon",
+		)
+
+		qrterminal.Generate(uri, qrterminal.L, os.Stderr)
+	}
 
 	if serial != nil {
 		log.Println("success:", *serial)
@@ -86,8 +120,10 @@ func (y *Yubikey) Register(profile string, requireTouch bool) error {
 	return nil
 }
 
-// Remove removes yubikey as mfa device from AWS, then otp config from yubikey, then cached session
-func (y *Yubikey) Remove(profile string, val credentials.Value) error {
+// Remove removes yubikey as mfa device from AWS, then the backend's stored OTP credential, then cached session.
+// mfaDevice selects which of the user's MFA devices to remove by name; when empty the
+// caller's own MFA serial is used, preserving the original single-device behavior.
+func (y *Yubikey) Remove(ctx context.Context, profile string, val credentials.Value, mfaDevice string) error {
 	sess, err := session.NewSessionWithOptions(session.Options{
 		Config: aws.Config{
 			Region:      aws.String(y.ProfileSection.Region),
@@ -107,17 +143,25 @@ func (y *Yubikey) Remove(profile string, val credentials.Value) error {
 	// }
 	log.Printf("Found access key  ****************%s", val.AccessKeyID[len(val.AccessKeyID)-4:])
 
-	device, err := yubikey.New()
+	dev, err := device.Get(y.backendName(), y.Keyring)
+	if err != nil {
+		return err
+	}
+	if pa, ok := dev.(device.PromptAware); ok && y.Prompt != nil {
+		pa.SetPrompt(y.Prompt)
+	}
+
+	m, err := mfa.New(sess, dev)
 	if err != nil {
 		return err
 	}
 
-	m, err := mfa.New(sess, device)
+	serial, err := m.ResolveSerial(y.Username, mfaDevice)
 	if err != nil {
 		return err
 	}
 
-	if err := m.Delete(y.Username); err != nil {
+	if err := m.Delete(y.Username, serial); err != nil {
 		return err
 	}
 